@@ -0,0 +1,309 @@
+//go:build !clive_v2
+// +build !clive_v2
+
+package clive
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// App, Command, Flag and Context alias the concrete urfave/cli v1 types so
+// the backend-agnostic logic in common.go can refer to them without caring
+// which major version of urfave/cli is in play.
+type (
+	App     = cli.App
+	Command = cli.Command
+	Flag    = cli.Flag
+	Context = cli.Context
+)
+
+// be is the backend implementation common.go's shared logic drives. This
+// file targets urfave/cli v1 and is used by default; build with -tags
+// clive_v2 to use v1Backend's counterpart in backend_v2.go instead.
+var be backend = v1Backend{}
+
+type v1Backend struct{}
+
+func (v1Backend) newApp() *App {
+	return cli.NewApp()
+}
+
+// flagName returns a flag's primary name - v1 folds aliases and short flags
+// into a single comma-joined GetName() (e.g. "verbose, v"). urfave/cli
+// registers each of those names on the FlagSet separately but never the
+// joined string itself, so callers look flags up by the first, unjoined name.
+func (v1Backend) flagName(flag Flag) string {
+	return strings.TrimSpace(strings.SplitN(flag.GetName(), ",", 2)[0])
+}
+
+// setCommands implements backend.setCommands. v1's App.Commands is a slice
+// of cli.Command values, not pointers, so each is dereferenced on the way in.
+func (v1Backend) setCommands(app *App, commands []*Command) {
+	cs := make([]Command, len(commands))
+	for i, command := range commands {
+		cs[i] = *command
+	}
+	app.Commands = cs
+}
+
+// appendSubcommand implements backend.appendSubcommand. v1's
+// Command.Subcommands is a slice of cli.Command values, not pointers.
+func (v1Backend) appendSubcommand(parent, child *Command) {
+	parent.Subcommands = append(parent.Subcommands, *child)
+}
+
+// subcommands implements backend.subcommands. v1's Command.Subcommands is a
+// slice of cli.Command values, so each is addressed on the way out.
+func (v1Backend) subcommands(command *Command) []*Command {
+	subs := make([]*Command, len(command.Subcommands))
+	for i := range command.Subcommands {
+		subs[i] = &command.Subcommands[i]
+	}
+	return subs
+}
+
+// commands implements backend.commands. v1's App.Commands is a slice of
+// cli.Command values, so each is addressed on the way out.
+func (v1Backend) commands(app *App) []*Command {
+	cs := make([]*Command, len(app.Commands))
+	for i := range app.Commands {
+		cs[i] = &app.Commands[i]
+	}
+	return cs
+}
+
+// honorRequired lets a caller ask for the resulting cli.Flag's Required to
+// always be false regardless of the `required:true` tag - validateField
+// enforces requiredness itself once hydration is complete, so build sets this
+// to false when WithConfigFile is in play (configFileBeforeFor applies the
+// file's values after urfave/cli's own parsing) to stop urfave/cli rejecting
+// the run before those values are ever applied.
+//
+//nolint:errcheck
+func (v1Backend) flag(fieldType reflect.StructField, cmdmeta commandMetadata, honorRequired bool) (flag Flag, err error) {
+	var (
+		name string
+		env  string
+	)
+
+	if cmdmeta.Name != "" {
+		name = strcase.ToKebab(cmdmeta.Name)
+	} else {
+		name = strcase.ToKebab(strings.TrimPrefix(fieldType.Name, "Flag"))
+	}
+	env = strcase.ToScreamingSnake(name)
+
+	// urfave/cli v1 takes aliases and short flags as extra comma-joined
+	// names on the same Name field, so "verbose" plus a "v" short flag
+	// becomes the string "verbose, v".
+	nameField := name
+	if aliases := append(append([]string{}, cmdmeta.Alias...), cmdmeta.Short...); len(aliases) > 0 {
+		nameField = strings.Join(append([]string{name}, aliases...), ", ")
+	}
+
+	cmdmeta.Default = strings.Trim(cmdmeta.Default, "'")
+
+	switch fieldType.Type.String() {
+	case "int":
+		def, _ := strconv.ParseInt(cmdmeta.Default, 10, 64)
+		flag = cli.IntFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    int(def),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "int64":
+		def, _ := strconv.ParseInt(cmdmeta.Default, 10, 64)
+		flag = cli.Int64Flag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "uint":
+		def, _ := strconv.ParseUint(cmdmeta.Default, 10, 64)
+		flag = cli.UintFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    uint(def),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "uint64":
+		def, _ := strconv.ParseUint(cmdmeta.Default, 10, 64)
+		flag = cli.Uint64Flag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "float32":
+		def, _ := strconv.ParseFloat(cmdmeta.Default, 32)
+		flag = cli.Float64Flag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "float64":
+		def, _ := strconv.ParseFloat(cmdmeta.Default, 64)
+		flag = cli.Float64Flag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "bool":
+		def, _ := strconv.ParseBool(cmdmeta.Default)
+		if !def {
+			flag = cli.BoolFlag{
+				Name:     nameField,
+				EnvVar:   env,
+				Hidden:   cmdmeta.Hidden,
+				Required: cmdmeta.Required && honorRequired,
+				Usage:    cmdmeta.Usage,
+			}
+		} else {
+			flag = cli.BoolTFlag{
+				Name:     nameField,
+				EnvVar:   env,
+				Hidden:   cmdmeta.Hidden,
+				Required: cmdmeta.Required && honorRequired,
+				Usage:    cmdmeta.Usage,
+			}
+		}
+
+	case "string":
+		flag = cli.StringFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    cmdmeta.Default,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "time.Duration":
+		def, _ := time.ParseDuration(cmdmeta.Default)
+		flag = cli.DurationFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "[]int":
+		var def *cli.IntSlice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			def = &cli.IntSlice{}
+			for _, s := range strings.Split(cmdmeta.Default, ",") {
+				d, _ := strconv.Atoi(s)
+				*def = append(*def, d)
+			}
+		}
+		flag = cli.IntSliceFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "[]int64":
+		var def *cli.Int64Slice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			def = &cli.Int64Slice{}
+			for _, s := range strings.Split(cmdmeta.Default, ",") {
+				d, _ := strconv.Atoi(s)
+				*def = append(*def, int64(d))
+			}
+		}
+		flag = cli.Int64SliceFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	// urfave/cli does not have unsigned types yet
+	// case "[]uint":
+	// 	flag = cli.IntSliceFlag{
+	// 		Name:   name,
+	// 		EnvVar: env,
+	// 		Hidden: cmdmeta.Hidden,
+	// 		Usage:  cmdmeta.Usage,
+	// 	}
+
+	// case "[]uint64":
+	// 	flag = cli.Int64SliceFlag{
+	// 		Name:   name,
+	// 		EnvVar: env,
+	// 		Hidden: cmdmeta.Hidden,
+	// 		Usage:  cmdmeta.Usage,
+	// 	}
+
+	case "[]string":
+		var def *cli.StringSlice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			def = &cli.StringSlice{}
+			*def = strings.Split(cmdmeta.Default, ",")
+		}
+		flag = cli.StringSliceFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	default:
+		// types outside the hard-coded list above can still be registered as
+		// a GenericFlag, provided a pointer to them implements FlagValue or
+		// encoding.TextUnmarshaler - e.g. net.IP, uuid.UUID or an enum type
+		// with its own UnmarshalText.
+		if !supportsGenericFlag(fieldType.Type) {
+			err = errors.Errorf("unsupported flag generator type: %s", fieldType.Type.String())
+			break
+		}
+		flag = cli.GenericFlag{
+			Name:     nameField,
+			EnvVar:   env,
+			Value:    newTextValue(fieldType.Type),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+	}
+
+	return flag, err
+}
@@ -0,0 +1,279 @@
+//go:build clive_v2
+// +build clive_v2
+
+package clive
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// App, Command, Flag and Context alias the concrete urfave/cli v2 types so
+// the backend-agnostic logic in common.go can refer to them without caring
+// which major version of urfave/cli is in play.
+type (
+	App     = cli.App
+	Command = cli.Command
+	Flag    = cli.Flag
+	Context = cli.Context
+)
+
+// be is the backend implementation common.go's shared logic drives. This
+// file targets urfave/cli v2 (build with -tags clive_v2); v2Backend's
+// counterpart, v1Backend, lives in backend_v1.go and is used by default.
+var be backend = v2Backend{}
+
+type v2Backend struct{}
+
+func (v2Backend) newApp() *App {
+	return cli.NewApp()
+}
+
+// flagName returns a flag's primary name - v2's cli.Flag exposes it as the
+// first element of Names() rather than v1's single-value GetName().
+func (v2Backend) flagName(flag Flag) string {
+	return flag.Names()[0]
+}
+
+// setCommands implements backend.setCommands. v2's App.Commands is already a
+// slice of *cli.Command, matching commands as-is.
+func (v2Backend) setCommands(app *App, commands []*Command) {
+	app.Commands = commands
+}
+
+// appendSubcommand implements backend.appendSubcommand. v2's
+// Command.Subcommands is already a slice of *cli.Command.
+func (v2Backend) appendSubcommand(parent, child *Command) {
+	parent.Subcommands = append(parent.Subcommands, child)
+}
+
+// subcommands implements backend.subcommands. v2's Command.Subcommands is
+// already a []*cli.Command.
+func (v2Backend) subcommands(command *Command) []*Command {
+	return command.Subcommands
+}
+
+// commands implements backend.commands. v2's App.Commands is already a
+// []*cli.Command, matching as-is.
+func (v2Backend) commands(app *App) []*Command {
+	return app.Commands
+}
+
+// honorRequired lets a caller ask for the resulting cli.Flag's Required to
+// always be false regardless of the `required:true` tag - validateField
+// enforces requiredness itself once hydration is complete, so build sets this
+// to false when WithConfigFile is in play (configFileBeforeFor applies the
+// file's values after urfave/cli's own parsing) to stop urfave/cli rejecting
+// the run before those values are ever applied.
+//
+//nolint:errcheck
+func (v2Backend) flag(fieldType reflect.StructField, cmdmeta commandMetadata, honorRequired bool) (flag Flag, err error) {
+	var (
+		name string
+		env  string
+	)
+
+	if cmdmeta.Name != "" {
+		name = strcase.ToKebab(cmdmeta.Name)
+	} else {
+		name = strcase.ToKebab(strings.TrimPrefix(fieldType.Name, "Flag"))
+	}
+	env = strcase.ToScreamingSnake(name)
+
+	// v2 keeps aliases and short flags in their own Aliases slice rather
+	// than folding them into Name.
+	aliases := append(append([]string{}, cmdmeta.Alias...), cmdmeta.Short...)
+
+	cmdmeta.Default = strings.Trim(cmdmeta.Default, "'")
+
+	switch fieldType.Type.String() {
+	case "int":
+		def, _ := strconv.ParseInt(cmdmeta.Default, 10, 64)
+		flag = &cli.IntFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    int(def),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "int64":
+		def, _ := strconv.ParseInt(cmdmeta.Default, 10, 64)
+		flag = &cli.Int64Flag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "uint":
+		def, _ := strconv.ParseUint(cmdmeta.Default, 10, 64)
+		flag = &cli.UintFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    uint(def),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "uint64":
+		def, _ := strconv.ParseUint(cmdmeta.Default, 10, 64)
+		flag = &cli.Uint64Flag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "float32":
+		def, _ := strconv.ParseFloat(cmdmeta.Default, 32)
+		flag = &cli.Float64Flag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "float64":
+		def, _ := strconv.ParseFloat(cmdmeta.Default, 64)
+		flag = &cli.Float64Flag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "bool":
+		def, _ := strconv.ParseBool(cmdmeta.Default)
+		flag = &cli.BoolFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "string":
+		flag = &cli.StringFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    cmdmeta.Default,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "time.Duration":
+		def, _ := time.ParseDuration(cmdmeta.Default)
+		flag = &cli.DurationFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "[]int":
+		var def *cli.IntSlice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			values := []int{}
+			for _, s := range strings.Split(cmdmeta.Default, ",") {
+				d, _ := strconv.Atoi(s)
+				values = append(values, d)
+			}
+			def = cli.NewIntSlice(values...)
+		}
+		flag = &cli.IntSliceFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "[]int64":
+		var def *cli.Int64Slice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			values := []int64{}
+			for _, s := range strings.Split(cmdmeta.Default, ",") {
+				d, _ := strconv.Atoi(s)
+				values = append(values, int64(d))
+			}
+			def = cli.NewInt64Slice(values...)
+		}
+		flag = &cli.Int64SliceFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	case "[]string":
+		var def *cli.StringSlice // must remain nil if unset
+		if cmdmeta.Default != "" {
+			def = cli.NewStringSlice(strings.Split(cmdmeta.Default, ",")...)
+		}
+		flag = &cli.StringSliceFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    def,
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+
+	default:
+		// types outside the hard-coded list above can still be registered as
+		// a GenericFlag, provided a pointer to them implements FlagValue or
+		// encoding.TextUnmarshaler - e.g. net.IP, uuid.UUID or an enum type
+		// with its own UnmarshalText.
+		if !supportsGenericFlag(fieldType.Type) {
+			err = errors.Errorf("unsupported flag generator type: %s", fieldType.Type.String())
+			break
+		}
+		flag = &cli.GenericFlag{
+			Name:     name,
+			Aliases:  aliases,
+			EnvVars:  []string{env},
+			Value:    newTextValue(fieldType.Type),
+			Hidden:   cmdmeta.Hidden,
+			Required: cmdmeta.Required && honorRequired,
+			Usage:    cmdmeta.Usage,
+		}
+	}
+
+	return flag, err
+}
@@ -0,0 +1,449 @@
+package clive
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Option configures optional Build behaviour, such as WithConfigFile. It is
+// passed into Build alongside the command structs, and picked out of the
+// variadic objs list by type.
+type Option func(*buildOptions)
+
+type buildOptions struct {
+	configFile *configFileOptions
+	completion bool
+}
+
+// backend captures the handful of operations that genuinely differ between
+// urfave/cli v1 and v2 - value vs pointer commands, comma-joined vs separate
+// alias names, EnvVar vs EnvVars, and so on. Everything else (the reflection
+// walk from a decorated struct to a command/flag tree) is identical either
+// way, so it is written once below and shared by both implementations, in
+// backend_v1.go and backend_v2.go respectively.
+type backend interface {
+	// newApp returns an empty App for build to populate.
+	newApp() *App
+	// flag builds a Flag from one Flag-prefixed struct field. honorRequired
+	// set to false keeps the flag optional as far as the underlying
+	// urfave/cli parser is concerned, even if cmdmeta.Required is set - see
+	// the honorRequired doc comment on each implementation for why.
+	flag(fieldType reflect.StructField, cmdmeta commandMetadata, honorRequired bool) (Flag, error)
+	// flagName returns a flag's primary name, for use in Context lookups.
+	flagName(flag Flag) string
+	// setCommands assigns a multi-command App's top-level Commands.
+	setCommands(app *App, commands []*Command)
+	// appendSubcommand adds child to parent's Subcommands.
+	appendSubcommand(parent, child *Command)
+	// subcommands returns command's Subcommands as a uniform []*Command,
+	// regardless of whether the concrete backend stores them by value (v1)
+	// or by pointer (v2).
+	subcommands(command *Command) []*Command
+	// commands returns app's top-level Commands as a uniform []*Command,
+	// regardless of whether the concrete backend stores them by value (v1)
+	// or by pointer (v2).
+	commands(app *App) []*Command
+}
+
+// Build constructs a urfave/cli App from an instance of a decorated struct.
+// Since it is designed to be used 1. on initialisation and; 2. with static
+// data that is compile-time only - it does not return an error but instead
+// panics. The idea is you will do all your setup once and as long as it
+// doesn't change this will never break, so there is little need to pass
+// errors back.
+//
+// objs may also contain Options, such as WithConfigFile, alongside the
+// command structs - they are recognised by type and applied to the build
+// rather than treated as another command.
+//
+// Build targets urfave/cli v1 by default; build with -tags clive_v2 to
+// target v2 instead (see backend_v2.go). The public API is identical either
+// way.
+func Build(objs ...interface{}) (c *App) {
+	c, err := build(objs...)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// Flags is a helper function for use within a command Action function. It takes
+// an instance of the struct that was used to generate the command and the
+// cli.Context pointer that is passed to the action function. It will then
+// call the necessary flag access functions (such as c.String("...")) and return
+// an instance of the input struct with the necessary fields set.
+//
+// If any field tagged `required:true` was left unset, or any field tagged
+// with `validate:...` fails its validator, Flags returns a non-nil
+// *ValidationError listing every failure so the Action function can surface
+// them all at once instead of bailing out on the first bad flag.
+func Flags(obj interface{}, c *Context) (result interface{}, err error) {
+	if obj == nil {
+		panic("obj is null")
+	}
+
+	objValue := reflect.ValueOf(obj)
+	for objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+
+	objType := objValue.Type()
+
+	resultValue := reflect.New(objType).Elem()
+
+	validation := &ValidationError{}
+
+	for i := 0; i < objType.NumField(); i++ {
+		fieldType := objType.Field(i)
+		cmdmeta, err := parseMeta(fieldType.Tag.Get("cli"))
+		if err != nil {
+			panic(err)
+		}
+
+		if strings.HasPrefix(fieldType.Name, "Flag") {
+			flag, err := be.flag(fieldType, cmdmeta, true)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to generate flag from struct field"))
+			}
+
+			name := be.flagName(flag)
+			field := resultValue.FieldByName(fieldType.Name)
+
+			switch fieldType.Type.String() {
+			case "int":
+				field.SetInt(int64(c.Int(name)))
+			case "int64":
+				field.SetInt(c.Int64(name))
+			case "uint":
+				field.SetUint(uint64(c.Uint(name)))
+			case "uint64":
+				field.SetUint(c.Uint64(name))
+			case "float32":
+				field.SetFloat(c.Float64(name))
+			case "float64":
+				field.SetFloat(c.Float64(name))
+			case "bool":
+				field.SetBool(c.Bool(name))
+			case "string":
+				field.SetString(c.String(name))
+			case "time.Duration":
+				field.SetInt(c.Duration(name).Nanoseconds())
+			case "[]int":
+				field.Set(genericSliceOf(c.IntSlice(name)))
+			case "[]int64":
+				field.Set(genericSliceOf(c.Int64Slice(name)))
+			case "[]string":
+				field.Set(genericSliceOf(c.StringSlice(name)))
+			default:
+				if !supportsGenericFlag(fieldType.Type) {
+					panic("unsupported type")
+				}
+				if tv, ok := c.Generic(name).(*textValue); ok && tv.value.IsValid() {
+					field.Set(tv.value)
+				}
+			}
+
+			validateField(name, field, c.IsSet(name), cmdmeta, validation)
+		}
+	}
+
+	if len(validation.Failures) > 0 {
+		return resultValue.Interface(), validation
+	}
+
+	return resultValue.Interface(), nil
+}
+
+// FlagsFor is the subcommand-tree counterpart to Flags. Given the same root
+// struct passed to Build and a path of field names leading down through
+// nested subcommand structs, it resolves the struct embedded at that path and
+// hydrates it from c, exactly as Flags would if called directly on it. Leaf
+// commands deep in a subcommand tree can use this instead of reaching into
+// their parent's fields manually.
+func FlagsFor(obj interface{}, c *Context, path ...string) (result interface{}, err error) {
+	if obj == nil {
+		panic("obj is null")
+	}
+
+	objValue := reflect.ValueOf(obj)
+	for objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+
+	for _, name := range path {
+		objValue = objValue.FieldByName(name)
+		if !objValue.IsValid() {
+			return nil, errors.Errorf("no field named %q found while resolving subcommand path %v", name, path)
+		}
+	}
+
+	return Flags(objValue.Interface(), c)
+}
+
+func build(objs ...interface{}) (c *App, err error) {
+	c = be.newApp()
+
+	// Options are resolved in their own pass, before any command is built,
+	// so that a WithConfigFile appearing anywhere in objs still affects
+	// every command's flags - not just the ones that happen to follow it.
+	var opts buildOptions
+	for _, obj := range objs {
+		if opt, ok := obj.(Option); ok {
+			opt(&opts)
+		}
+	}
+
+	// a required field that WithConfigFile also covers can't be satisfied
+	// from urfave/cli's own parsing, since configFileBeforeFor applies the
+	// file after urfave/cli has already checked required flags - so leave
+	// those flags optional as far as urfave/cli is concerned and let
+	// validateField enforce requiredness once the file has been applied.
+	honorRequired := opts.configFile == nil
+
+	commands := []*Command{}
+	for _, obj := range objs {
+		if _, ok := obj.(Option); ok {
+			continue
+		}
+
+		var command *Command
+		command, err = commandFromObject(obj, honorRequired, opts.configFile, nil)
+		if err != nil {
+			return
+		}
+		commands = append(commands, command)
+	}
+
+	// if it's a one-command application, there's no need for a subcommand so
+	// just move the command's contents into the root object, aka the 'App' -
+	// including any of its own Subcommands, so a single struct expressing a
+	// whole tree (e.g. a `git remote add`-style nested command) still has
+	// that tree reachable from the root rather than silently dropped. This
+	// decision is made on the caller-supplied commands alone - the hidden
+	// completion command WithCompletion adds below is not itself a command
+	// worth collapsing around, and must not stop a single-struct app from
+	// getting its Action/Flags hoisted onto the App.
+	if len(commands) == 1 {
+		c.Usage = commands[0].Usage
+		c.Action = commands[0].Action
+		c.Flags = commands[0].Flags
+		c.Before = commands[0].Before
+		if subs := be.subcommands(commands[0]); len(subs) > 0 {
+			be.setCommands(c, subs)
+		}
+	} else {
+		be.setCommands(c, commands)
+		c.Flags = nil
+	}
+
+	if opts.completion {
+		be.setCommands(c, append(be.commands(c), completionCommand()))
+		c.EnableBashCompletion = true
+	}
+
+	return
+}
+
+// commandFromObject builds obj's Command, recursing into any nested
+// subcommand fields. cfg is non-nil when WithConfigFile is in play, in
+// which case every (sub)command in the tree gets its own Before hook
+// (see configFileBeforeFor) rather than a single hook at the root - keyPath
+// is the chain of subcommand names from the root down to obj, used by that
+// hook to find obj's own table in the parsed config file.
+func commandFromObject(obj interface{}, honorRequired bool, cfg *configFileOptions, keyPath []string) (command *Command, err error) {
+	if obj == nil {
+		return nil, errors.New("obj is null")
+	}
+
+	// recursively dereference
+	objValue := reflect.ValueOf(obj)
+	for objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+
+	// anonymous structs (struct{ ... }{}) are not allowed
+	objType := objValue.Type()
+	if objType.Name() == "" {
+		return nil, errors.New("need a named struct type to determine command name")
+	}
+
+	// the first field must be an embedded cli.Command struct
+	command, err = getCommand(objType.Field(0), objValue.Field(0))
+	if err != nil {
+		return nil, err
+	}
+	command.Name = strings.ToLower(objType.Name())
+
+	var completions []completeSpec
+
+	for i := 1; i < objType.NumField(); i++ {
+		fieldType := objType.Field(i)
+
+		cmdmeta, err := parseMeta(fieldType.Tag.Get("cli"))
+		if err != nil {
+			return nil, err
+		}
+
+		// automatically turn fields that begin with Flag into cli.Flag objects
+		if strings.HasPrefix(fieldType.Name, "Flag") {
+			flag, err := be.flag(fieldType, cmdmeta, honorRequired)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to generate flag from struct field")
+			}
+			command.Flags = append(command.Flags, flag)
+			if cmdmeta.Complete != "" {
+				completions = append(completions, parseComplete(cmdmeta.Complete))
+			}
+			continue
+		}
+
+		// any other exported field that is itself a clive-decorated struct
+		// (i.e. has an embedded cli.Command as its own field 0) becomes a
+		// nested subcommand, recursively, so trees of arbitrary depth can be
+		// expressed as a single struct literal.
+		if isSubcommand(fieldType.Type) {
+			childPath := append(append([]string{}, keyPath...), strings.ToLower(fieldType.Type.Name()))
+			subcommand, err := commandFromObject(objValue.Field(i).Interface(), honorRequired, cfg, childPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to build subcommand from field %q", fieldType.Name)
+			}
+			be.appendSubcommand(command, subcommand)
+		}
+	}
+
+	command.BashComplete = makeBashComplete(command, completions)
+
+	if cfg != nil {
+		command.Before = configFileBeforeFor(*cfg, obj, keyPath)
+	}
+
+	return command, nil
+}
+
+// commandType is the type of the embedded field every clive-decorated struct
+// must carry as its first field.
+var commandType = reflect.TypeOf(Command{})
+
+// isSubcommand reports whether t has the same shape Build and
+// commandFromObject require of a top-level command: a named struct whose
+// first field is an embedded cli.Command.
+func isSubcommand(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.Name() == "" || t.NumField() == 0 {
+		return false
+	}
+	first := t.Field(0)
+	return first.Name == "Command" && first.Type == commandType
+}
+
+func getCommand(fieldType reflect.StructField, fieldValue reflect.Value) (c *Command, err error) {
+	if fieldType.Name != "Command" {
+		return nil, errors.New("first field must be an embedded cli.Command")
+	}
+
+	if fieldValue.Kind() != reflect.Struct {
+		return nil, errors.New("expected Command field to be a struct (specifically, an embedded cli.Command struct)")
+	}
+
+	cmd, ok := fieldValue.Interface().(Command)
+	if !ok {
+		return nil, errors.New("failed to cast Command field to a cli.Command object")
+	}
+
+	cmdmeta, err := parseMeta(fieldType.Tag.Get("cli"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cmdmeta tag on the embedded cli.Command struct")
+	}
+	cmd.Usage = cmdmeta.Usage
+	cmd.Flags = []Flag{}
+
+	return &cmd, nil
+}
+
+type commandMetadata struct {
+	Name     string
+	Usage    string
+	Hidden   bool
+	Default  string
+	Required bool
+	Validate string
+	Alias    []string
+	Short    []string
+	Complete string
+}
+
+func parseMeta(s string) (cmdmeta commandMetadata, err error) {
+	// this code allows strings to be placed inside single-quotes in order to
+	// escape comma characters.
+	quotes := false
+	sections := strings.FieldsFunc(s, func(r rune) bool {
+		if r == '\'' && !quotes {
+			quotes = true
+		} else if r == '\'' && quotes {
+			quotes = false
+		}
+		if r == ',' && !quotes {
+			return true
+		}
+		return false
+	})
+	for _, section := range sections {
+		keyvalue := strings.SplitN(section, ":", 2)
+		if len(keyvalue) == 2 {
+			switch keyvalue[0] {
+			case "name":
+				cmdmeta.Name = keyvalue[1]
+			case "usage":
+				cmdmeta.Usage = strings.Trim(keyvalue[1], "'") // trim single-quotes
+			case "hidden":
+				cmdmeta.Hidden, err = strconv.ParseBool(keyvalue[1])
+				if err != nil {
+					err = errors.Wrap(err, "failed to parse 'hidden' as a bool")
+				}
+			case "default":
+				cmdmeta.Default = keyvalue[1]
+			case "required":
+				cmdmeta.Required, err = strconv.ParseBool(keyvalue[1])
+				if err != nil {
+					err = errors.Wrap(err, "failed to parse 'required' as a bool")
+				}
+			case "validate":
+				cmdmeta.Validate = strings.Trim(keyvalue[1], "'") // trim single-quotes
+			case "alias":
+				cmdmeta.Alias = strings.Split(strings.Trim(keyvalue[1], "'"), ",")
+			case "short":
+				cmdmeta.Short = strings.Split(strings.Trim(keyvalue[1], "'"), ",")
+			case "complete":
+				cmdmeta.Complete = strings.Trim(keyvalue[1], "'") // trim single-quotes
+			default:
+				err = errors.Errorf("unknown command tag: '%s:%s'", keyvalue[0], keyvalue[1])
+			}
+		} else {
+			err = errors.Errorf("malformed tag: '%s'", section)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return cmdmeta, err
+}
+
+// given a generic slice type, returns a reflected version of that slice with
+// all elements inserted.
+func genericSliceOf(slice interface{}) reflect.Value {
+	sliceValue := reflect.ValueOf(slice)
+	length := sliceValue.Len()
+	sliceAddr := reflect.New(reflect.MakeSlice(
+		reflect.TypeOf(slice),
+		length,
+		length,
+	).Type())
+	for i := 0; i < length; i++ {
+		value := sliceValue.Index(i)
+		ap := reflect.Append(sliceAddr.Elem(), value)
+		sliceAddr.Elem().Set(ap)
+	}
+	return sliceAddr.Elem()
+}
@@ -0,0 +1,33 @@
+package clive
+
+import "testing"
+
+type buildTestRemote struct {
+	Command
+	FlagURL string `cli:"name:url"`
+}
+
+type buildTestRoot struct {
+	Command
+	Remote buildTestRemote
+}
+
+// TestBuildCollapsesSingleCommandButKeepsSubcommands guards the headline
+// "single struct expressing a nested command tree" example: when Build is
+// given exactly one top-level struct, its contents are hoisted onto the
+// App itself, but its own Subcommands must still end up reachable from the
+// App rather than being silently dropped.
+func TestBuildCollapsesSingleCommandButKeepsSubcommands(t *testing.T) {
+	app, err := build(&buildTestRoot{})
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+
+	if len(app.Commands) != 1 {
+		t.Fatalf("expected the root's single subcommand to be promoted to the App, got %d top-level commands", len(app.Commands))
+	}
+
+	if got := app.Commands[0].Name; got != "buildtestremote" {
+		t.Fatalf("expected the promoted subcommand to be %q, got %q", "buildtestremote", got)
+	}
+}
@@ -0,0 +1,121 @@
+package clive
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+type completionTestRoot struct {
+	Command
+	FlagEnv string `cli:"complete:'values:dev,staging,prod'"`
+}
+
+// captureStdout runs fn with os.Stdout redirected, returning everything fn
+// wrote to it - WithCompletion's completion command and App.Run's own
+// --generate-bash-completion handling both write straight to os.Stdout
+// rather than returning a string.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	original := os.Stdout
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("io.Copy() error = %v", err)
+	}
+	return buf.String()
+}
+
+// TestWithCompletionGeneratesScript guards WithCompletion's headline
+// feature: a hidden "completion" subcommand that, given a shell name, prints
+// an installable script naming the App by its Name field (read from
+// c.App.Name), here set to "mycli" in place of urfave/cli's default of the
+// running binary's base name.
+func TestWithCompletionGeneratesScript(t *testing.T) {
+	root := &completionTestRoot{}
+	app, err := build(root, WithCompletion())
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	app.Name = "mycli"
+
+	var runErr error
+	output := captureStdout(t, func() {
+		runErr = app.Run([]string{"mycli", "completion", "bash"})
+	})
+	if runErr != nil {
+		t.Fatalf("app.Run() error = %v", runErr)
+	}
+
+	if !strings.Contains(output, "mycli") {
+		t.Fatalf("completion script = %q, want it to reference the App name %q", output, "mycli")
+	}
+	if !strings.Contains(output, "complete -F") {
+		t.Fatalf("completion script = %q, want a bash `complete -F` registration", output)
+	}
+}
+
+// TestWithCompletionPreservesSingleCommandCollapse guards the interaction
+// between WithCompletion and build's single-command collapse (see
+// TestBuildCollapsesSingleCommandButKeepsSubcommands): the hidden
+// "completion" command WithCompletion adds must not itself count towards
+// that collapse decision, or a single-struct App would permanently lose its
+// hoisted Action/Flags and start rejecting its own flags at the root.
+func TestWithCompletionPreservesSingleCommandCollapse(t *testing.T) {
+	var gotEnv string
+	root := &completionTestRoot{}
+	root.Action = func(c *Context) error {
+		result, err := Flags(root, c)
+		if err != nil {
+			return err
+		}
+		gotEnv = result.(completionTestRoot).FlagEnv
+		return nil
+	}
+
+	app, err := build(root, WithCompletion())
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	app.Name = "mycli"
+
+	if err := app.Run([]string{"mycli", "--env", "dev"}); err != nil {
+		t.Fatalf("app.Run() error = %v, want the root flag to work without a subcommand", err)
+	}
+
+	if want := "dev"; gotEnv != want {
+		t.Fatalf("FlagEnv = %q, want %q", gotEnv, want)
+	}
+}
+
+// TestCompleteTagCandidates guards parsing of the `complete:'values:...'`
+// tag: parseComplete must yield a spec whose candidates() are the listed
+// values, in order. It does not run the BashComplete func that
+// makeBashComplete builds from such specs.
+func TestCompleteTagCandidates(t *testing.T) {
+	spec := parseComplete("values:dev,staging,prod")
+	got := spec.candidates()
+
+	want := []string{"dev", "staging", "prod"}
+	if len(got) != len(want) {
+		t.Fatalf("candidates() = %v, want %v", got, want)
+	}
+	for i, v := range want {
+		if got[i] != v {
+			t.Fatalf("candidates()[%d] = %q, want %q", i, got[i], v)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package clive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Format parses a config file's raw bytes into a tree of nested maps, so
+// WithConfigFile can walk the result the same way regardless of the source
+// file's encoding.
+type Format interface {
+	// Extensions lists the file extensions (without the leading dot) this
+	// Format should be used for, e.g. "yaml", "yml".
+	Extensions() []string
+	// Unmarshal parses data into a tree of maps keyed by the kebab-case flag
+	// names used elsewhere in clive.
+	Unmarshal(data []byte) (map[string]interface{}, error)
+}
+
+// YAML is the built-in Format for .yaml/.yml config files.
+type YAML struct{}
+
+// Extensions implements Format.
+func (YAML) Extensions() []string { return []string{"yaml", "yml"} }
+
+// Unmarshal implements Format.
+func (YAML) Unmarshal(data []byte) (tree map[string]interface{}, err error) {
+	err = yaml.Unmarshal(data, &tree)
+	return
+}
+
+// TOML is the built-in Format for .toml config files.
+type TOML struct{}
+
+// Extensions implements Format.
+func (TOML) Extensions() []string { return []string{"toml"} }
+
+// Unmarshal implements Format.
+func (TOML) Unmarshal(data []byte) (tree map[string]interface{}, err error) {
+	err = toml.Unmarshal(data, &tree)
+	return
+}
+
+// JSON is the built-in Format for .json config files.
+type JSON struct{}
+
+// Extensions implements Format.
+func (JSON) Extensions() []string { return []string{"json"} }
+
+// Unmarshal implements Format.
+func (JSON) Unmarshal(data []byte) (tree map[string]interface{}, err error) {
+	err = json.Unmarshal(data, &tree)
+	return
+}
+
+// configFileOptions is the state accumulated by WithConfigFile until build()
+// has the full command tree available to apply it against.
+type configFileOptions struct {
+	pathFlag string
+	formats  []Format
+}
+
+// WithConfigFile returns a Build Option that, before the app's Action runs,
+// reads the file named by the pathFlag flag and fills in defaults for any
+// flag left unset on the command line or via environment variable. The
+// file's format is chosen by matching its extension against formats; if none
+// are given, YAML, TOML and JSON are all registered.
+//
+// Precedence, highest first: explicit CLI argument, environment variable,
+// config file, struct `default:` tag.
+//
+// Fields tagged `required:true` are still enforced, but via Flags'
+// validation pass rather than urfave/cli's own flag parsing - urfave/cli
+// checks required flags before Before runs, which would otherwise reject a
+// run whose required value only exists in the config file.
+func WithConfigFile(pathFlag string, formats ...Format) Option {
+	if len(formats) == 0 {
+		formats = []Format{YAML{}, TOML{}, JSON{}}
+	}
+	return func(o *buildOptions) {
+		o.configFile = &configFileOptions{pathFlag: pathFlag, formats: formats}
+	}
+}
+
+// formatFor returns the Format registered for path's extension, or nil if
+// none matches.
+func formatFor(formats []Format, path string) Format {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, format := range formats {
+		for _, candidate := range format.Extensions() {
+			if candidate == ext {
+				return format
+			}
+		}
+	}
+	return nil
+}
+
+// asStringMap normalises a decoded nested table into map[string]interface{}.
+// JSON and TOML already decode tables that way, but yaml.v2 decodes nested
+// mappings as map[interface{}]interface{}, so without this a subcommand's
+// table would never be found under YAML.
+func asStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, value := range m {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			out[key] = value
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// descendConfigTree walks tree down through keyPath - the chain of
+// subcommand names from the root to the command whose table is wanted - and
+// returns the table found at the end, or false if any hop is missing or
+// isn't itself a table.
+func descendConfigTree(tree map[string]interface{}, keyPath []string) (map[string]interface{}, bool) {
+	for _, key := range keyPath {
+		value, ok := tree[key]
+		if !ok {
+			return nil, false
+		}
+		tree, ok = asStringMap(value)
+		if !ok {
+			return nil, false
+		}
+	}
+	return tree, true
+}
+
+// applyConfigFields applies tree's values to any of obj's own Flag* fields
+// not already set via the command line or environment variable. It never
+// descends into nested subcommand structs - each subcommand gets its own
+// Before hook (attached in commandFromObject), applying its own table
+// against its own *Context, since a subcommand's flags don't exist in any
+// ancestor context's FlagSet until that subcommand is actually invoked.
+func applyConfigFields(obj interface{}, c *Context, tree map[string]interface{}) error {
+	objValue := reflect.ValueOf(obj)
+	for objValue.Kind() == reflect.Ptr {
+		objValue = objValue.Elem()
+	}
+	objType := objValue.Type()
+
+	for i := 0; i < objType.NumField(); i++ {
+		fieldType := objType.Field(i)
+
+		if !strings.HasPrefix(fieldType.Name, "Flag") {
+			continue
+		}
+
+		cmdmeta, err := parseMeta(fieldType.Tag.Get("cli"))
+		if err != nil {
+			return err
+		}
+
+		flag, err := be.flag(fieldType, cmdmeta, true)
+		if err != nil {
+			return errors.Wrap(err, "failed to generate flag from struct field")
+		}
+
+		name := be.flagName(flag)
+		if c.IsSet(name) || os.Getenv(strcase.ToScreamingSnake(name)) != "" {
+			continue
+		}
+
+		value, ok := tree[name]
+		if !ok {
+			continue
+		}
+
+		if err := c.Set(name, fmt.Sprint(value)); err != nil {
+			return errors.Wrapf(err, "failed to apply config file value for flag %q", name)
+		}
+	}
+
+	return nil
+}
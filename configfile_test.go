@@ -0,0 +1,76 @@
+package clive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type Remote struct {
+	Command
+	FlagURL string `cli:"name:url"`
+}
+
+type configFileTestRoot struct {
+	Command
+	FlagConfig string `cli:"name:config"`
+	Remote     Remote
+}
+
+// TestWithConfigFileAppliesNestedTable guards two failure modes in applying
+// a config file value to a nested subcommand's flag: a YAML nested mapping
+// decoding as map[interface{}]interface{} (silently skipped if not
+// normalised) and a config value for a not-yet-parsed subcommand flag being
+// applied against the wrong *Context's FlagSet ("no such flag").
+func TestWithConfigFileAppliesNestedTable(t *testing.T) {
+	cases := []struct {
+		name     string
+		filename string
+		contents string
+	}{
+		{
+			name:     "yaml",
+			filename: "config.yaml",
+			contents: "remote:\n  url: https://example.com/repo.git\n",
+		},
+		{
+			name:     "json",
+			filename: "config.json",
+			contents: `{"remote": {"url": "https://example.com/repo.git"}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tc.filename)
+			if err := os.WriteFile(path, []byte(tc.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var gotURL string
+			root := &configFileTestRoot{}
+			root.Remote.Action = func(c *Context) error {
+				flags, err := FlagsFor(root, c, "Remote")
+				if err != nil {
+					return err
+				}
+				gotURL = flags.(Remote).FlagURL
+				return nil
+			}
+
+			app, err := build(root, WithConfigFile("config"))
+			if err != nil {
+				t.Fatalf("build() error = %v", err)
+			}
+
+			if err := app.Run([]string{"app", "--config", path, "remote"}); err != nil {
+				t.Fatalf("app.Run() error = %v", err)
+			}
+
+			if want := "https://example.com/repo.git"; gotURL != want {
+				t.Fatalf("Remote.FlagURL = %q, want %q", gotURL, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !clive_v2
+// +build !clive_v2
+
+package clive
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// configFileBeforeFor builds the cli.BeforeFunc attached to a single
+// command's own Before - the root App's, when Build collapses to one
+// top-level command, or each (sub)command's otherwise. It loads
+// opts.pathFlag's file, descends to obj's own table via keyPath (the chain
+// of subcommand names from the root down to obj), and applies just that
+// table against obj's own Flag* fields, using this invocation's own
+// *cli.Context - a value destined for a subcommand's flag only exists in
+// that subcommand's own FlagSet once urfave/cli has actually dispatched to
+// it, never in an ancestor context's.
+func configFileBeforeFor(opts configFileOptions, obj interface{}, keyPath []string) cli.BeforeFunc {
+	return func(c *cli.Context) error {
+		path := c.GlobalString(opts.pathFlag)
+		if path == "" {
+			path = c.String(opts.pathFlag)
+		}
+		if path == "" {
+			return nil
+		}
+
+		format := formatFor(opts.formats, path)
+		if format == nil {
+			return errors.Errorf("no registered Format recognises the extension of config file %q", path)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read config file")
+		}
+
+		tree, err := format.Unmarshal(data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse config file %q", path)
+		}
+
+		subtree, ok := descendConfigTree(tree, keyPath)
+		if !ok {
+			return nil
+		}
+
+		return applyConfigFields(obj, c, subtree)
+	}
+}
@@ -0,0 +1,69 @@
+package clive
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// FlagValue may be implemented by a field's type to take over its own flag
+// parsing and display when the backend's flag method's hard-coded type
+// switch doesn't apply. It is tried before encoding.TextUnmarshaler, so a
+// type that wants a CLI-specific string form distinct from its text
+// encoding can still use this instead.
+type FlagValue interface {
+	Set(string) error
+	String() string
+}
+
+var (
+	flagValueType       = reflect.TypeOf((*FlagValue)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// supportsGenericFlag reports whether a pointer to a value of type t
+// implements FlagValue or encoding.TextUnmarshaler - the two interfaces the
+// backend's flag method falls back to for field types outside its
+// hard-coded switch, such as net.IP or uuid.UUID. url.URL qualifies for
+// neither, since *url.URL only implements encoding.BinaryUnmarshaler.
+func supportsGenericFlag(t reflect.Type) bool {
+	p := reflect.PtrTo(t)
+	return p.Implements(flagValueType) || p.Implements(textUnmarshalerType)
+}
+
+// textValue adapts an arbitrary FlagValue or encoding.TextUnmarshaler type to
+// the small Set/String shape both urfave/cli v1's cli.Generic and v2's
+// cli.Generic share, so each backend's flag method can register it as a
+// GenericFlag with the same couple of lines.
+type textValue struct {
+	value reflect.Value // addressable; reflect.New(fieldType).Elem()
+}
+
+// newTextValue allocates a fresh, addressable zero value of t for a
+// GenericFlag's Value to wrap.
+func newTextValue(t reflect.Type) *textValue {
+	return &textValue{value: reflect.New(t).Elem()}
+}
+
+func (t *textValue) String() string {
+	if !t.value.IsValid() {
+		return ""
+	}
+	if s, ok := t.value.Addr().Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(t.value.Interface())
+}
+
+func (t *textValue) Set(s string) error {
+	addr := t.value.Addr().Interface()
+	if fv, ok := addr.(FlagValue); ok {
+		return fv.Set(s)
+	}
+	if tu, ok := addr.(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+	return errors.Errorf("%s implements neither clive.FlagValue nor encoding.TextUnmarshaler", t.value.Type())
+}
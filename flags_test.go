@@ -0,0 +1,93 @@
+package clive
+
+import "testing"
+
+type flagsTestAliasRoot struct {
+	Command
+	FlagVerbose bool   `cli:"short:'v'"`
+	FlagOutput  string `cli:"alias:'out'"`
+}
+
+// TestFlagsAliasAndShortNames guards the alias/short tag grammar end to end:
+// a flag declared with `short:'v'` must be settable as -v, and one declared
+// with `alias:'out'` must be settable as --out, with both still readable
+// back through Flags under their primary (un-aliased) field name.
+func TestFlagsAliasAndShortNames(t *testing.T) {
+	var got flagsTestAliasRoot
+	root := &flagsTestAliasRoot{}
+	root.Action = func(c *Context) error {
+		result, err := Flags(root, c)
+		if err != nil {
+			return err
+		}
+		got = result.(flagsTestAliasRoot)
+		return nil
+	}
+
+	app, err := build(root)
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+
+	if err := app.Run([]string{"app", "-v", "--out", "report.json"}); err != nil {
+		t.Fatalf("app.Run() error = %v", err)
+	}
+
+	if !got.FlagVerbose {
+		t.Fatalf("FlagVerbose = false, want true (short flag -v wasn't applied)")
+	}
+	if want := "report.json"; got.FlagOutput != want {
+		t.Fatalf("FlagOutput = %q, want %q (alias --out wasn't applied)", got.FlagOutput, want)
+	}
+}
+
+// level is a test-only custom flag type implementing encoding.TextUnmarshaler,
+// standing in for the likes of net.IP or an enum in a real caller.
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		*l = 0
+	}
+	return nil
+}
+
+type flagsTestCustomTypeRoot struct {
+	Command
+	FlagLevel level `cli:"name:level"`
+}
+
+// TestFlagsCustomType guards the GenericFlag fallback for field types outside
+// the hard-coded switch in backend_v1.go/backend_v2.go: a type implementing
+// only encoding.TextUnmarshaler (not FlagValue) must still be parsed from its
+// flag's string value and hydrated back onto the struct by Flags.
+func TestFlagsCustomType(t *testing.T) {
+	var got flagsTestCustomTypeRoot
+	root := &flagsTestCustomTypeRoot{}
+	root.Action = func(c *Context) error {
+		result, err := Flags(root, c)
+		if err != nil {
+			return err
+		}
+		got = result.(flagsTestCustomTypeRoot)
+		return nil
+	}
+
+	app, err := build(root)
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+
+	if err := app.Run([]string{"app", "--level", "high"}); err != nil {
+		t.Fatalf("app.Run() error = %v", err)
+	}
+
+	if want := level(2); got.FlagLevel != want {
+		t.Fatalf("FlagLevel = %v, want %v", got.FlagLevel, want)
+	}
+}
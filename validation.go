@@ -0,0 +1,150 @@
+package clive
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FlagValidationFailure describes a single flag that failed the `required` or
+// `validate` tags declared on its struct field.
+type FlagValidationFailure struct {
+	Flag   string
+	Reason string
+}
+
+// ValidationError is returned by Flags when one or more fields fail their
+// `required` or `validate` tags. It collects every failure so an Action
+// function can report them all in a single message instead of stopping at
+// the first bad flag.
+type ValidationError struct {
+	Failures []FlagValidationFailure
+}
+
+func (v *ValidationError) Error() string {
+	reasons := make([]string, len(v.Failures))
+	for i, failure := range v.Failures {
+		reasons[i] = fmt.Sprintf("%s: %s", failure.Flag, failure.Reason)
+	}
+	return fmt.Sprintf("validation failed for %d flag(s): %s", len(v.Failures), strings.Join(reasons, "; "))
+}
+
+// validateField checks a single hydrated struct field against its `required`
+// and `validate` tags, appending any failures to validation. isSet reports
+// whether the flag was actually supplied (by the user, the config file, or
+// an env var) - it, not the hydrated value's zero-ness, is what `required`
+// enforces, since a legitimate value (0, "", false) is indistinguishable
+// from an unset one once hydrated.
+func validateField(name string, field reflect.Value, isSet bool, cmdmeta commandMetadata, validation *ValidationError) {
+	if cmdmeta.Required && !isSet {
+		validation.Failures = append(validation.Failures, FlagValidationFailure{
+			Flag:   name,
+			Reason: "is required",
+		})
+	}
+
+	if cmdmeta.Validate == "" {
+		return
+	}
+
+	for _, rule := range parseValidators(cmdmeta.Validate) {
+		if reason := rule.check(field); reason != "" {
+			validation.Failures = append(validation.Failures, FlagValidationFailure{
+				Flag:   name,
+				Reason: reason,
+			})
+		}
+	}
+}
+
+// validator is a single parsed rule from a `validate:'...'` tag, such as
+// `min:1` or `oneof:a,b,c`. Multiple rules may be chained with `|`.
+type validator struct {
+	kind string
+	arg  string
+}
+
+// parseValidators splits a validate tag's value into its chained rules.
+// `|` is the chain separator, but it's also regex alternation syntax, so an
+// arg may wrap itself in double-quotes to protect any `|` it contains from
+// being mistaken for a chain separator - e.g. `regex:"^(dev|staging)$"`.
+// This mirrors parseMeta's single-quote escaping of `,` one level down,
+// using `"` instead of `'` since the outer tag already consumed the latter.
+func parseValidators(spec string) (validators []validator) {
+	quoted := false
+	for _, rule := range strings.FieldsFunc(spec, func(r rune) bool {
+		if r == '"' {
+			quoted = !quoted
+		}
+		return r == '|' && !quoted
+	}) {
+		keyvalue := strings.SplitN(rule, ":", 2)
+		v := validator{kind: keyvalue[0]}
+		if len(keyvalue) == 2 {
+			v.arg = strings.Trim(keyvalue[1], `"`)
+		}
+		validators = append(validators, v)
+	}
+	return validators
+}
+
+// check returns a human-readable failure reason, or an empty string if field
+// satisfies the rule.
+func (v validator) check(field reflect.Value) string {
+	switch v.kind {
+	case "regex":
+		re, err := regexp.Compile(v.arg)
+		if err != nil {
+			return fmt.Sprintf("invalid validate:regex pattern %q: %s", v.arg, err)
+		}
+		if !re.MatchString(fmt.Sprint(field.Interface())) {
+			return fmt.Sprintf("does not match pattern %q", v.arg)
+		}
+
+	case "oneof":
+		value := fmt.Sprint(field.Interface())
+		for _, option := range strings.Split(v.arg, ",") {
+			if option == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of: %s", v.arg)
+
+	case "min":
+		min, err := strconv.ParseFloat(v.arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid validate:min value %q: %s", v.arg, err)
+		}
+		if numericValue(field) < min {
+			return fmt.Sprintf("must be at least %s", v.arg)
+		}
+
+	case "max":
+		max, err := strconv.ParseFloat(v.arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid validate:max value %q: %s", v.arg, err)
+		}
+		if numericValue(field) > max {
+			return fmt.Sprintf("must be at most %s", v.arg)
+		}
+	}
+
+	return ""
+}
+
+// numericValue returns field's value as a float64 regardless of its
+// underlying int/uint/float kind, for use by the min/max validators.
+func numericValue(field reflect.Value) float64 {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		return field.Float()
+	default:
+		return 0
+	}
+}
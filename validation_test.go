@@ -0,0 +1,65 @@
+package clive
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestValidateFieldRequired guards against treating a required flag's
+// zero value as "unset" - a required bool flag explicitly passed as false,
+// or a required int/string explicitly passed as 0/"", must still pass.
+func TestValidateFieldRequired(t *testing.T) {
+	cases := []struct {
+		name    string
+		isSet   bool
+		value   interface{}
+		wantErr bool
+	}{
+		{name: "unset bool fails", isSet: false, value: false, wantErr: true},
+		{name: "explicit false bool passes", isSet: true, value: false, wantErr: false},
+		{name: "explicit true bool passes", isSet: true, value: true, wantErr: false},
+		{name: "unset int fails", isSet: false, value: 0, wantErr: true},
+		{name: "explicit zero int passes", isSet: true, value: 0, wantErr: false},
+		{name: "explicit non-zero int passes", isSet: true, value: 5, wantErr: false},
+		{name: "unset string fails", isSet: false, value: "", wantErr: true},
+		{name: "explicit empty string passes", isSet: true, value: "", wantErr: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			validation := &ValidationError{}
+			validateField("flag", reflect.ValueOf(tc.value), tc.isSet, commandMetadata{Required: true}, validation)
+			if got := len(validation.Failures) > 0; got != tc.wantErr {
+				t.Fatalf("validateField() failed = %v, want %v (failures: %v)", got, tc.wantErr, validation.Failures)
+			}
+		})
+	}
+}
+
+// TestValidateFieldRegexAlternation guards against the `|` that chains
+// validators being confused with the `|` of regex alternation - a
+// `regex:"..."` arg quoted with double-quotes must keep its pipes intact,
+// and an unquoted chain of rules must still split on its own pipes.
+func TestValidateFieldRegexAlternation(t *testing.T) {
+	cases := []struct {
+		name     string
+		validate string
+		value    interface{}
+		wantErr  bool
+	}{
+		{name: "quoted alternation matches", validate: `regex:"^(dev|staging|prod)$"`, value: "dev", wantErr: false},
+		{name: "quoted alternation rejects", validate: `regex:"^(dev|staging|prod)$"`, value: "qa", wantErr: true},
+		{name: "chained rules still split on bare pipe", validate: "min:1|max:10", value: 5, wantErr: false},
+		{name: "chained rule violation still caught", validate: "min:1|max:10", value: 20, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			validation := &ValidationError{}
+			validateField("flag", reflect.ValueOf(tc.value), true, commandMetadata{Validate: tc.validate}, validation)
+			if got := len(validation.Failures) > 0; got != tc.wantErr {
+				t.Fatalf("validateField() failed = %v, want %v (failures: %v)", got, tc.wantErr, validation.Failures)
+			}
+		})
+	}
+}